@@ -0,0 +1,225 @@
+// Copyright © 2019 Marton Magyar
+
+// SPDX-License-Identifier: MIT
+// see https://spdx.org/licenses/
+
+package tapfile
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+	"io/ioutil"
+)
+
+/*
+BASIC array TAP file functions.
+
+This file provides functions to store and write numeric and string BASIC arrays as a header/data
+block pair in a TAP file.
+*/
+
+// setArrayFilename encodes and sets a new file name into the given 10-byte filename field
+func setArrayFilename(filename *[10]byte, f string, encoder FilenameEncoder) error {
+
+	encoded, err := encoder.Encode(f)
+	if err != nil {
+		return err
+	}
+
+	*filename = encoded
+
+	return nil
+}
+
+// setVariableName validates and encodes a BASIC array variable name (A..Z), offset by base
+func setVariableName(variablename *uint8, v byte, base uint8) error {
+
+	if v < 'A' || v > 'Z' {
+		return fmt.Errorf("Illegal array variable name: %q, must be A..Z", v)
+	}
+
+	*variablename = base + (v - 'A' + 1)
+
+	return nil
+}
+
+// NumArrayData holds essential information and data for a numeric array in a TAP file
+type NumArrayData struct {
+	filename     [10]byte // loading name of the program. filled with spaces (CHR$(32))
+	datablock    []byte   // the array data (may be empty)
+	variablename uint8    // (1..26 meaning A..Z) +128
+}
+
+// NewNumArrayData initializes and returns a NumArrayData structure. By default the file name is
+// encoded as 7-bit ASCII; pass WithFilenameEncoder to use a different FilenameEncoder.
+func NewNumArrayData(name string, variablename byte, data io.Reader, opts ...Option) (*NumArrayData, error) {
+
+	o := defaultOptions()
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	t := new(NumArrayData)
+
+	if err := setArrayFilename(&t.filename, name, o.encoder); err != nil {
+		return nil, err
+	}
+	if err := setVariableName(&t.variablename, variablename, 128); err != nil {
+		return nil, err
+	}
+
+	var err error
+	t.datablock, err = ioutil.ReadAll(data)
+	if err != nil {
+		t.datablock = nil
+		return nil, err
+	}
+
+	return t, nil
+}
+
+// writeHeader writes the numeric array header data through a BlockWriter
+func (b *NumArrayData) writeHeader(w BlockWriter) error {
+
+	endianness := binary.LittleEndian
+
+	if err := binary.Write(w, endianness, tapHeaderBlock); err != nil {
+		return err
+	}
+	if err := binary.Write(w, endianness, tapNumArrayHeaderType); err != nil {
+		return err
+	}
+	if err := binary.Write(w, endianness, b.filename); err != nil {
+		return err
+	}
+	if err := binary.Write(w, endianness, uint16(len(b.datablock))); err != nil {
+		return err
+	}
+	if err := binary.Write(w, endianness, uint8(0)); err != nil {
+		return err
+	}
+	if err := binary.Write(w, endianness, b.variablename); err != nil {
+		return err
+	}
+	if err := binary.Write(w, endianness, tapUnused); err != nil {
+		return err
+	}
+
+	return w.CompleteBlock()
+}
+
+// writeData writes the numeric array data block through a BlockWriter
+func (b *NumArrayData) writeData(w BlockWriter) error {
+
+	endianness := binary.LittleEndian
+
+	if err := binary.Write(w, endianness, tapDataBlock); err != nil {
+		return err
+	}
+	if err := binary.Write(w, endianness, b.datablock); err != nil {
+		return err
+	}
+
+	return w.CompleteBlock()
+}
+
+// Write writes a numeric array as a header/data block pair
+func (b *NumArrayData) Write(w BlockWriter) error {
+
+	if err := b.writeHeader(w); err != nil {
+		return err
+	}
+
+	return b.writeData(w)
+}
+
+// StringArrayData holds essential information and data for a string array in a TAP file
+type StringArrayData struct {
+	filename     [10]byte // loading name of the program. filled with spaces (CHR$(32))
+	datablock    []byte   // the array data (may be empty)
+	variablename uint8    // (1..26 meaning A..Z) +192
+}
+
+// NewStringArrayData initializes and returns a StringArrayData structure. By default the file name
+// is encoded as 7-bit ASCII; pass WithFilenameEncoder to use a different FilenameEncoder.
+func NewStringArrayData(name string, variablename byte, data io.Reader, opts ...Option) (*StringArrayData, error) {
+
+	o := defaultOptions()
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	t := new(StringArrayData)
+
+	if err := setArrayFilename(&t.filename, name, o.encoder); err != nil {
+		return nil, err
+	}
+	if err := setVariableName(&t.variablename, variablename, 192); err != nil {
+		return nil, err
+	}
+
+	var err error
+	t.datablock, err = ioutil.ReadAll(data)
+	if err != nil {
+		t.datablock = nil
+		return nil, err
+	}
+
+	return t, nil
+}
+
+// writeHeader writes the string array header data through a BlockWriter
+func (b *StringArrayData) writeHeader(w BlockWriter) error {
+
+	endianness := binary.LittleEndian
+
+	if err := binary.Write(w, endianness, tapHeaderBlock); err != nil {
+		return err
+	}
+	if err := binary.Write(w, endianness, tapStringArrayHeaderType); err != nil {
+		return err
+	}
+	if err := binary.Write(w, endianness, b.filename); err != nil {
+		return err
+	}
+	if err := binary.Write(w, endianness, uint16(len(b.datablock))); err != nil {
+		return err
+	}
+	if err := binary.Write(w, endianness, uint8(0)); err != nil {
+		return err
+	}
+	if err := binary.Write(w, endianness, b.variablename); err != nil {
+		return err
+	}
+	if err := binary.Write(w, endianness, tapUnused); err != nil {
+		return err
+	}
+
+	return w.CompleteBlock()
+}
+
+// writeData writes the string array data block through a BlockWriter
+func (b *StringArrayData) writeData(w BlockWriter) error {
+
+	endianness := binary.LittleEndian
+
+	if err := binary.Write(w, endianness, tapDataBlock); err != nil {
+		return err
+	}
+	if err := binary.Write(w, endianness, b.datablock); err != nil {
+		return err
+	}
+
+	return w.CompleteBlock()
+}
+
+// Write writes a string array as a header/data block pair
+func (b *StringArrayData) Write(w BlockWriter) error {
+
+	if err := b.writeHeader(w); err != nil {
+		return err
+	}
+
+	return b.writeData(w)
+}
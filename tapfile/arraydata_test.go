@@ -0,0 +1,112 @@
+// Copyright © 2019 Marton Magyar
+
+// SPDX-License-Identifier: MIT
+// see https://spdx.org/licenses/
+
+package tapfile
+
+import (
+	"bytes"
+	"io/ioutil"
+	"testing"
+)
+
+// TestNumArrayData_WriteReadRoundTrip checks that a NumArrayData entry round-trips through Write and
+// back through Reader.Next/Read unchanged.
+func TestNumArrayData_WriteReadRoundTrip(t *testing.T) {
+
+	const name = "DATA"
+	const variablename = 'D'
+	data := []byte{1, 2, 3, 4, 5}
+
+	arr, err := NewNumArrayData(name, variablename, bytes.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var buf bytes.Buffer
+	if err := arr.Write(NewTAPfileBlockWriter(&buf)); err != nil {
+		t.Fatal(err)
+	}
+
+	r := NewReader(&buf)
+
+	hdr, err := r.Next()
+	if err != nil {
+		t.Fatal(err)
+	}
+	nh, ok := hdr.(NumArrayHeader)
+	if !ok {
+		t.Fatalf("expected a NumArrayHeader, got %T", hdr)
+	}
+	if nh.Filename != name {
+		t.Errorf("Filename = %q, want %q", nh.Filename, name)
+	}
+	if nh.DataLength != uint16(len(data)) {
+		t.Errorf("DataLength = %d, want %d", nh.DataLength, len(data))
+	}
+	if nh.VariableName != variablename {
+		t.Errorf("VariableName = %q, want %q", nh.VariableName, variablename)
+	}
+
+	if _, err := r.Next(); err != nil {
+		t.Fatal(err)
+	}
+	got, err := ioutil.ReadAll(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, data) {
+		t.Errorf("data = % X, want % X", got, data)
+	}
+}
+
+// TestStringArrayData_WriteReadRoundTrip checks that a StringArrayData entry round-trips through
+// Write and back through Reader.Next/Read unchanged.
+func TestStringArrayData_WriteReadRoundTrip(t *testing.T) {
+
+	const name = "NAMES"
+	const variablename = 'N'
+	data := []byte("ALICE\x0DBOB\x0D")
+
+	arr, err := NewStringArrayData(name, variablename, bytes.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var buf bytes.Buffer
+	if err := arr.Write(NewTAPfileBlockWriter(&buf)); err != nil {
+		t.Fatal(err)
+	}
+
+	r := NewReader(&buf)
+
+	hdr, err := r.Next()
+	if err != nil {
+		t.Fatal(err)
+	}
+	sh, ok := hdr.(StringArrayHeader)
+	if !ok {
+		t.Fatalf("expected a StringArrayHeader, got %T", hdr)
+	}
+	if sh.Filename != name {
+		t.Errorf("Filename = %q, want %q", sh.Filename, name)
+	}
+	if sh.DataLength != uint16(len(data)) {
+		t.Errorf("DataLength = %d, want %d", sh.DataLength, len(data))
+	}
+	if sh.VariableName != variablename {
+		t.Errorf("VariableName = %q, want %q", sh.VariableName, variablename)
+	}
+
+	if _, err := r.Next(); err != nil {
+		t.Fatal(err)
+	}
+	got, err := ioutil.ReadAll(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, data) {
+		t.Errorf("data = % X, want % X", got, data)
+	}
+}
@@ -0,0 +1,125 @@
+// Copyright © 2019 Marton Magyar
+
+// SPDX-License-Identifier: MIT
+// see https://spdx.org/licenses/
+
+package tapfile
+
+import (
+	"encoding/binary"
+	"io"
+	"io/ioutil"
+)
+
+/*
+BASIC program TAP file functions.
+
+This file provides functions to store and write a BASIC program as a header/data block pair in a TAP file.
+*/
+
+// BASICdata holds essential information and data for a BASIC program in a TAP file
+type BASICdata struct {
+	filename      [10]byte // loading name of the program. filled with spaces (CHR$(32))
+	program       []byte   // the BASIC program listing (may be empty)
+	autostartline uint16   // LINE parameter of SAVE command. 32768 means "no auto-loading"; 0..9999 are valid line numbers
+}
+
+// setFilename encodes and sets a new file name using the given encoder
+func (b *BASICdata) setFilename(f string, encoder FilenameEncoder) error {
+
+	filename, err := encoder.Encode(f)
+	if err != nil {
+		return err
+	}
+
+	b.filename = filename
+
+	return nil
+}
+
+// setProgram sets the BASIC program listing
+func (b *BASICdata) setProgram(program io.Reader) error {
+
+	var err error
+	b.program, err = ioutil.ReadAll(program)
+	if err != nil {
+		b.program = nil
+		return err
+	}
+
+	return nil
+}
+
+// NewBASICdata initializes and returns a BASICdata structure. By default the file name is encoded
+// as 7-bit ASCII; pass WithFilenameEncoder to use a different FilenameEncoder.
+func NewBASICdata(name string, program io.Reader, autostartline uint16, opts ...Option) (*BASICdata, error) {
+
+	o := defaultOptions()
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	t := new(BASICdata)
+
+	if err := t.setFilename(name, o.encoder); err != nil {
+		return nil, err
+	}
+	if err := t.setProgram(program); err != nil {
+		return nil, err
+	}
+	t.autostartline = autostartline
+
+	return t, nil
+}
+
+// writeHeader writes the BASIC program header data through a BlockWriter
+func (b *BASICdata) writeHeader(w BlockWriter) error {
+
+	endianness := binary.LittleEndian
+
+	if err := binary.Write(w, endianness, tapHeaderBlock); err != nil {
+		return err
+	}
+	if err := binary.Write(w, endianness, tapBASICHeaderType); err != nil {
+		return err
+	}
+	if err := binary.Write(w, endianness, b.filename); err != nil {
+		return err
+	}
+	if err := binary.Write(w, endianness, uint16(len(b.program))); err != nil {
+		return err
+	}
+	if err := binary.Write(w, endianness, b.autostartline); err != nil {
+		return err
+	}
+	if err := binary.Write(w, endianness, uint16(len(b.program))); err != nil {
+		return err
+	}
+
+	return w.CompleteBlock()
+}
+
+// writeData writes the BASIC program data block through a BlockWriter
+func (b *BASICdata) writeData(w BlockWriter) error {
+
+	endianness := binary.LittleEndian
+
+	if err := binary.Write(w, endianness, tapDataBlock); err != nil {
+		return err
+	}
+	if err := binary.Write(w, endianness, b.program); err != nil {
+		return err
+	}
+
+	return w.CompleteBlock()
+}
+
+// Write writes a BASIC program as a header/data block pair
+func (b *BASICdata) Write(w BlockWriter) error {
+
+	if err := b.writeHeader(w); err != nil {
+		return err
+	}
+
+	return b.writeData(w)
+}
@@ -0,0 +1,65 @@
+// Copyright © 2019 Marton Magyar
+
+// SPDX-License-Identifier: MIT
+// see https://spdx.org/licenses/
+
+package tapfile
+
+import (
+	"bytes"
+	"io/ioutil"
+	"testing"
+)
+
+// TestBASICdata_WriteReadRoundTrip checks that a BASICdata entry round-trips through Write and back
+// through Reader.Next/Read unchanged.
+func TestBASICdata_WriteReadRoundTrip(t *testing.T) {
+
+	const name = "LOADER"
+	const program = "10 PRINT \"HI\"\n"
+	const autostartline = 10
+
+	basic, err := NewBASICdata(name, bytes.NewReader([]byte(program)), autostartline)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var buf bytes.Buffer
+	if err := basic.Write(NewTAPfileBlockWriter(&buf)); err != nil {
+		t.Fatal(err)
+	}
+
+	r := NewReader(&buf)
+
+	hdr, err := r.Next()
+	if err != nil {
+		t.Fatal(err)
+	}
+	bh, ok := hdr.(BASICHeader)
+	if !ok {
+		t.Fatalf("expected a BASICHeader, got %T", hdr)
+	}
+	if bh.Filename != name {
+		t.Errorf("Filename = %q, want %q", bh.Filename, name)
+	}
+	if bh.DataLength != uint16(len(program)) {
+		t.Errorf("DataLength = %d, want %d", bh.DataLength, len(program))
+	}
+	if bh.AutostartLine != autostartline {
+		t.Errorf("AutostartLine = %d, want %d", bh.AutostartLine, autostartline)
+	}
+	if bh.ProgramLength != uint16(len(program)) {
+		t.Errorf("ProgramLength = %d, want %d", bh.ProgramLength, len(program))
+	}
+
+	if _, err := r.Next(); err != nil {
+		t.Fatal(err)
+	}
+	data, err := ioutil.ReadAll(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != program {
+		t.Errorf("program = %q, want %q", data, program)
+	}
+}
@@ -6,13 +6,12 @@
 package tapfile
 
 import (
+	"bytes"
 	"encoding/binary"
 	"fmt"
 	"io"
 	"io/ioutil"
 	"math"
-	"strconv"
-	"strings"
 )
 
 /*
@@ -28,17 +27,15 @@ type BINdata struct {
 	startaddress uint16   // start address of the code in the Z80 address space, in case of a SCREEN$ header = 16384
 }
 
-// setFilename validates and sets a new file name
-func (b *BINdata) setFilename(f string) error {
+// setFilename encodes and sets a new file name using the given encoder
+func (b *BINdata) setFilename(f string, encoder FilenameEncoder) error {
 
-	quoted := strconv.QuoteToASCII(f)
-	asciif := strings.Trim(quoted, "\"")
-	if f != asciif {
-		return fmt.Errorf("Illegal characters in tap file name: %s", asciif)
+	filename, err := encoder.Encode(f)
+	if err != nil {
+		return err
 	}
 
-	copy(b.filename[:], "          ")
-	copy(b.filename[:], asciif)
+	b.filename = filename
 
 	return nil
 }
@@ -72,12 +69,18 @@ func (b *BINdata) setStartAddress(a uint16) error {
 	return nil
 }
 
-// NewBINdata initializes and returns a BINdata structure
-func NewBINdata(name string, bindata io.Reader, startaddress uint16) (*BINdata, error) {
+// NewBINdata initializes and returns a BINdata structure. By default the file name is encoded as
+// 7-bit ASCII; pass WithFilenameEncoder to use a different FilenameEncoder.
+func NewBINdata(name string, bindata io.Reader, startaddress uint16, opts ...Option) (*BINdata, error) {
+
+	o := defaultOptions()
+	for _, opt := range opts {
+		opt(&o)
+	}
 
 	t := new(BINdata)
 
-	if err := t.setFilename(name); err != nil {
+	if err := t.setFilename(name, o.encoder); err != nil {
 		return nil, err
 	}
 	if err := t.setBinData(bindata); err != nil {
@@ -90,15 +93,47 @@ func NewBINdata(name string, bindata io.Reader, startaddress uint16) (*BINdata,
 	return t, nil
 }
 
-// Read reads data into a BINdata structure from an io.Reader providing a raw TAP file stream
+// Read reads a BINdata structure from an io.Reader providing a raw TAP file stream, positioned at
+// the start of a bytes header followed by its data block
 func (b *BINdata) Read(r io.Reader) error {
 
-	//TODO: fill with function!
-	return nil
+	tr := NewReader(r)
+
+	hdr, err := tr.Next()
+	if err != nil {
+		return err
+	}
+
+	bh, ok := hdr.(BytesHeader)
+	if !ok {
+		return fmt.Errorf("Expected a bytes header, got %T", hdr)
+	}
+
+	dhdr, err := tr.Next()
+	if err != nil {
+		return err
+	}
+	if _, ok := dhdr.(DataBlock); !ok {
+		return fmt.Errorf("Expected a data block, got %T", dhdr)
+	}
+
+	data, err := ioutil.ReadAll(tr)
+	if err != nil {
+		return err
+	}
+
+	if err := b.setFilename(bh.Filename, ASCIIEncoder{}); err != nil {
+		return err
+	}
+	if err := b.setBinData(bytes.NewReader(data)); err != nil {
+		return err
+	}
+
+	return b.setStartAddress(bh.StartAddress)
 }
 
-// writeHeader writes raw bytes header data into a specialized TAPfileBlockWriter
-func (b *BINdata) writeHeader(w *TAPfileBlockWriter) error {
+// writeHeader writes raw bytes header data through a BlockWriter
+func (b *BINdata) writeHeader(w BlockWriter) error {
 
 	endianness := binary.LittleEndian
 
@@ -124,8 +159,8 @@ func (b *BINdata) writeHeader(w *TAPfileBlockWriter) error {
 	return w.CompleteBlock()
 }
 
-// writeData writes raw binary block data into a specialized TAPfileBlockWriter
-func (b *BINdata) writeData(w *TAPfileBlockWriter) error {
+// writeData writes raw binary block data through a BlockWriter
+func (b *BINdata) writeData(w BlockWriter) error {
 
 	endianness := binary.LittleEndian
 
@@ -140,7 +175,7 @@ func (b *BINdata) writeData(w *TAPfileBlockWriter) error {
 }
 
 // Write writes a binary TAP file
-func (b *BINdata) Write(w *TAPfileBlockWriter) error {
+func (b *BINdata) Write(w BlockWriter) error {
 
 	if err := b.writeHeader(w); err != nil {
 		return err
@@ -0,0 +1,65 @@
+// Copyright © 2019 Marton Magyar
+
+// SPDX-License-Identifier: MIT
+// see https://spdx.org/licenses/
+
+package tapfile
+
+import (
+	"bytes"
+	"testing"
+)
+
+// TestBINdata_WriteReadRoundTrip checks that a BINdata round-trips through Write/Read unchanged.
+func TestBINdata_WriteReadRoundTrip(t *testing.T) {
+
+	bin, err := NewBINdata("CODE", bytes.NewReader([]byte{0xF3, 0xAF, 0xC9}), 32768)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var buf bytes.Buffer
+	if err := bin.Write(NewTAPfileBlockWriter(&buf)); err != nil {
+		t.Fatal(err)
+	}
+
+	var got BINdata
+	if err := got.Read(&buf); err != nil {
+		t.Fatal(err)
+	}
+
+	if got.filename != bin.filename {
+		t.Errorf("filename = %q, want %q", got.filename, bin.filename)
+	}
+	if !bytes.Equal(got.datablock, bin.datablock) {
+		t.Errorf("datablock = % X, want % X", got.datablock, bin.datablock)
+	}
+	if got.startaddress != bin.startaddress {
+		t.Errorf("startaddress = %d, want %d", got.startaddress, bin.startaddress)
+	}
+}
+
+// TestBINdata_ReadRejectsMissingDataBlock checks that Read errors out, rather than silently
+// succeeding with an empty data block, when a header is immediately followed by another header
+// instead of its data block.
+func TestBINdata_ReadRejectsMissingDataBlock(t *testing.T) {
+
+	bin, err := NewBINdata("CODE", bytes.NewReader(nil), 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var buf bytes.Buffer
+	w := NewTAPfileBlockWriter(&buf)
+	if err := bin.writeHeader(w); err != nil {
+		t.Fatal(err)
+	}
+	if err := bin.writeHeader(w); err != nil {
+		t.Fatal(err)
+	}
+
+	var got BINdata
+	if err := got.Read(&buf); err == nil {
+		t.Fatal("Read succeeded on a header with no following data block, want an error")
+	}
+}
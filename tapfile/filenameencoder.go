@@ -0,0 +1,228 @@
+// Copyright © 2019 Marton Magyar
+
+// SPDX-License-Identifier: MIT
+// see https://spdx.org/licenses/
+
+package tapfile
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+/*
+Filename encoding functions.
+
+TAP filenames are a fixed 10-byte field. This file provides pluggable encoders translating a Go
+string into that field: ASCIIEncoder keeps the original 7-bit ASCII-only behaviour, while
+ZXSpectrumEncoder maps UTF-8 input through the ZX Spectrum character set, including its block-graphic
+and tokenised-keyword ranges.
+*/
+
+// FilenameEncoder encodes a file name into the fixed 10-byte, space-padded field used by TAP headers
+type FilenameEncoder interface {
+	Encode(name string) ([10]byte, error)
+}
+
+// ASCIIEncoder accepts 7-bit ASCII file names only, rejecting anything else. This is the original
+// behaviour of BINdata.setFilename.
+type ASCIIEncoder struct{}
+
+// Encode implements FilenameEncoder
+func (ASCIIEncoder) Encode(name string) ([10]byte, error) {
+
+	var filename [10]byte
+
+	quoted := strconv.QuoteToASCII(name)
+	asciiname := strings.Trim(quoted, "\"")
+	if name != asciiname {
+		return filename, fmt.Errorf("Illegal characters in tap file name: %s", asciiname)
+	}
+
+	copy(filename[:], "          ")
+	copy(filename[:], asciiname)
+
+	return filename, nil
+}
+
+// ZXSpectrumEncoder maps a UTF-8 file name through the ZX Spectrum character set: ordinary ASCII
+// letters, digits and punctuation, £ (0x60) and © (0x7F) in place of the backtick and DEL, the 16
+// block-graphic characters (0x80-0x8F), and the tokenised BASIC keywords (0xA5-0xFF), so that
+// authentic tape filenames such as "£OADER" or keyword-decorated names can be produced.
+type ZXSpectrumEncoder struct{}
+
+// Encode implements FilenameEncoder
+func (ZXSpectrumEncoder) Encode(name string) ([10]byte, error) {
+
+	var filename [10]byte
+	copy(filename[:], "          ")
+
+	runes := []rune(name)
+	i, pos := 0, 0
+
+	for i < len(runes) && pos < len(filename) {
+		if token, n := matchZXToken(runes[i:]); n > 0 {
+			filename[pos] = token
+			i += n
+			pos++
+			continue
+		}
+
+		b, err := zxEncodeRune(runes[i])
+		if err != nil {
+			return filename, err
+		}
+		filename[pos] = b
+		i++
+		pos++
+	}
+
+	if i < len(runes) {
+		return filename, fmt.Errorf("tapfile: filename too long, encodes to more than %d bytes", len(filename))
+	}
+
+	return filename, nil
+}
+
+// £ and © replace the backtick and DEL in the ZX Spectrum character set
+const zxPoundSign = 0x60
+const zxCopyrightSign = 0x7F
+
+// zxEncodeRune encodes a single rune as one ZX Spectrum character set byte
+func zxEncodeRune(r rune) (byte, error) {
+
+	switch r {
+	case '£':
+		return zxPoundSign, nil
+	case '©':
+		return zxCopyrightSign, nil
+	}
+
+	if b, ok := zxGraphics[r]; ok {
+		return b, nil
+	}
+
+	if r >= 0x20 && r <= 0x7E && r != '`' {
+		return byte(r), nil
+	}
+
+	return 0, fmt.Errorf("tapfile: character %q has no ZX Spectrum character set encoding", r)
+}
+
+// zxGraphics maps the 16 block-graphic characters (0x80-0x8F) to their Unicode Block Elements
+// equivalent. The code for a given combination of filled quadrants is 0x80 + a 4-bit mask, bit0 =
+// top-left, bit1 = top-right, bit2 = bottom-left, bit3 = bottom-right.
+var zxGraphics = map[rune]byte{
+	' ': 0x80, // blank (no-break space, since a plain space already maps to itself)
+	'▘': 0x81,
+	'▝': 0x82,
+	'▀': 0x83,
+	'▖': 0x84,
+	'▌': 0x85,
+	'▞': 0x86,
+	'▛': 0x87,
+	'▗': 0x88,
+	'▚': 0x89,
+	'▐': 0x8A,
+	'▜': 0x8B,
+	'▄': 0x8C,
+	'▙': 0x8D,
+	'▟': 0x8E,
+	'█': 0x8F,
+}
+
+// zxGraphicsReverse is the inverse of zxGraphics, used by decodeZXByte to decode a block-graphic
+// byte back into its Unicode Block Elements rune.
+var zxGraphicsReverse = make(map[byte]rune, len(zxGraphics))
+
+func init() {
+	for r, b := range zxGraphics {
+		zxGraphicsReverse[b] = r
+	}
+}
+
+// decodeZXByte decodes a single raw TAP filename byte back into the string it represents under the
+// ZX Spectrum character set: the inverse of zxEncodeRune and matchZXToken. A byte produced by
+// ASCIIEncoder decodes to itself, since plain 7-bit ASCII is a subset of the same character set.
+func decodeZXByte(b byte) (string, error) {
+
+	switch b {
+	case zxPoundSign:
+		return "£", nil
+	case zxCopyrightSign:
+		return "©", nil
+	}
+
+	if r, ok := zxGraphicsReverse[b]; ok {
+		return string(r), nil
+	}
+
+	if b >= zxTokenBase {
+		return zxTokens[int(b)-zxTokenBase], nil
+	}
+
+	if b >= 0x20 && b <= 0x7E {
+		return string(rune(b)), nil
+	}
+
+	return "", fmt.Errorf("byte %#02x has no ZX Spectrum character set meaning", b)
+}
+
+// zxTokens lists the ZX Spectrum BASIC tokenised keywords, in order, starting at code 0xA5 (165)
+var zxTokens = []string{
+	"RND", "INKEY$", "PI", "FN", "POINT", "SCREEN$", "ATTR", "AT", "TAB", "VAL$",
+	"CODE", "VAL", "LEN", "SIN", "COS", "TAN", "ASN", "ACS", "ATN", "LN",
+	"EXP", "INT", "SQR", "SGN", "ABS", "PEEK", "IN", "USR", "STR$", "CHR$",
+	"NOT", "BIN", "OR", "AND", "<=", ">=", "<>", "LINE", "THEN", "TO",
+	"STEP", "DEF FN", "CAT", "FORMAT", "MOVE", "ERASE", "OPEN #", "CLOSE #", "MERGE", "VERIFY",
+	"BEEP", "CIRCLE", "INK", "PAPER", "FLASH", "BRIGHT", "INVERSE", "OVER", "OUT", "LPRINT",
+	"LLIST", "STOP", "READ", "DATA", "RESTORE", "NEW", "BORDER", "CONTINUE", "DIM", "REM",
+	"FOR", "GO TO", "GO SUB", "INPUT", "LOAD", "LIST", "LET", "PAUSE", "NEXT", "POKE",
+	"PRINT", "PLOT", "RUN", "SAVE", "RANDOMIZE", "IF", "CLS", "DRAW", "CLEAR", "RETURN",
+	"COPY",
+}
+
+const zxTokenBase = 0xA5
+
+// matchZXToken finds the longest tokenised keyword matching the start of runes, returning its
+// encoded byte and the number of runes it consumes, or (0, 0) if none matches
+func matchZXToken(runes []rune) (byte, int) {
+
+	best, bestLen := -1, 0
+
+	for i, token := range zxTokens {
+		n := len(token)
+		if n <= bestLen || n > len(runes) {
+			continue
+		}
+		if string(runes[:n]) == token {
+			best, bestLen = i, n
+		}
+	}
+
+	if best < 0 {
+		return 0, 0
+	}
+
+	return byte(zxTokenBase + best), bestLen
+}
+
+// Option configures the TAP entry constructors in this package, e.g. WithFilenameEncoder
+type Option func(*options)
+
+type options struct {
+	encoder FilenameEncoder
+}
+
+func defaultOptions() options {
+	return options{encoder: ASCIIEncoder{}}
+}
+
+// WithFilenameEncoder selects the FilenameEncoder used to encode a TAP entry's file name. The
+// default, matching prior behaviour, is ASCIIEncoder.
+func WithFilenameEncoder(encoder FilenameEncoder) Option {
+	return func(o *options) {
+		o.encoder = encoder
+	}
+}
@@ -0,0 +1,258 @@
+// Copyright © 2019 Marton Magyar
+
+// SPDX-License-Identifier: MIT
+// see https://spdx.org/licenses/
+
+package tapfile
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"strings"
+)
+
+/*
+TAP file reader functions.
+
+This file provides a streaming reader for TAP files, modeled after archive/tar.Reader: NewReader
+returns successive typed entries through Next, and the data belonging to the most recently returned
+DataBlock entry can then be streamed out through Read.
+*/
+
+// Header is implemented by the typed entries returned by Reader.Next: BytesHeader, BASICHeader,
+// NumArrayHeader, StringArrayHeader and DataBlock.
+type Header interface {
+	isHeader()
+}
+
+// BytesHeader describes a machine code (or other raw binary) header block
+type BytesHeader struct {
+	Filename     string
+	DataLength   uint16
+	StartAddress uint16
+}
+
+func (BytesHeader) isHeader() {}
+
+// BASICHeader describes a BASIC program header block
+type BASICHeader struct {
+	Filename      string
+	DataLength    uint16
+	AutostartLine uint16 // 32768 means "no auto-loading"
+	ProgramLength uint16
+}
+
+func (BASICHeader) isHeader() {}
+
+// NumArrayHeader describes a numeric array header block
+type NumArrayHeader struct {
+	Filename     string
+	DataLength   uint16
+	VariableName byte // 'A'..'Z'
+}
+
+func (NumArrayHeader) isHeader() {}
+
+// StringArrayHeader describes a string (alphanumeric) array header block
+type StringArrayHeader struct {
+	Filename     string
+	DataLength   uint16
+	VariableName byte // 'A'..'Z'
+}
+
+func (StringArrayHeader) isHeader() {}
+
+// DataBlock marks a raw data block. Its bytes are read through Reader.Read.
+type DataBlock struct {
+	Flag uint8
+}
+
+func (DataBlock) isHeader() {}
+
+// Reader reads successive entries from a TAP file stream.
+type Reader struct {
+	br               *TAPfileBlockReader
+	body             io.Reader
+	expectDataLength int // expected length of the next data block as declared by the last header, or -1 if not following a header
+}
+
+// NewReader creates a new Reader reading from r
+func NewReader(r io.Reader) *Reader {
+
+	t := new(Reader)
+
+	t.br = NewTAPfileBlockReader(r)
+	t.expectDataLength = -1
+
+	return t
+}
+
+// Next advances to the next entry in the TAP file stream. It returns io.EOF at the end of input.
+func (t *Reader) Next() (Header, error) {
+
+	t.body = nil
+
+	block, err := t.br.ReadBlock()
+	if err != nil {
+		return nil, err
+	}
+
+	flag, payload := block[0], block[1:]
+
+	if flag != tapHeaderBlock {
+		if t.expectDataLength >= 0 && len(payload) != t.expectDataLength {
+			return nil, fmt.Errorf("tapfile: data block is %d bytes, header declared datalength %d", len(payload), t.expectDataLength)
+		}
+		t.expectDataLength = -1
+		t.body = bytes.NewReader(payload)
+		return DataBlock{Flag: flag}, nil
+	}
+
+	hdr, err := parseHeader(payload)
+	if err != nil {
+		return nil, err
+	}
+
+	t.expectDataLength = int(dataLength(hdr))
+
+	return hdr, nil
+}
+
+// dataLength extracts the declared data block length carried by a Header
+func dataLength(hdr Header) uint16 {
+
+	switch h := hdr.(type) {
+	case BytesHeader:
+		return h.DataLength
+	case BASICHeader:
+		return h.DataLength
+	case NumArrayHeader:
+		return h.DataLength
+	case StringArrayHeader:
+		return h.DataLength
+	default:
+		return 0
+	}
+}
+
+// Read reads from the data block most recently returned by Next. It returns io.EOF if the current
+// entry is a header (headers carry no further body) or once the data block is exhausted.
+func (t *Reader) Read(p []byte) (int, error) {
+
+	if t.body == nil {
+		return 0, io.EOF
+	}
+
+	return t.body.Read(p)
+}
+
+// parseHeader decodes a header block's payload (the bytes following the flag byte) into its typed Header
+func parseHeader(payload []byte) (Header, error) {
+
+	if len(payload) < 1 {
+		return nil, fmt.Errorf("tapfile: truncated header block")
+	}
+
+	datatype, rest := payload[0], bytes.NewReader(payload[1:])
+	endianness := binary.LittleEndian
+
+	switch datatype {
+	case tapBytesHeader:
+		var h Block_BytesHeader
+		if err := readHeaderFields(rest, endianness, &h.filename, &h.datalength, &h.startaddress, &h.unused); err != nil {
+			return nil, err
+		}
+		filename, err := filenameToString(h.filename)
+		if err != nil {
+			return nil, err
+		}
+		return BytesHeader{
+			Filename:     filename,
+			DataLength:   h.datalength,
+			StartAddress: h.startaddress,
+		}, nil
+
+	case tapBASICHeaderType:
+		var h Block_BASICHeader
+		if err := readHeaderFields(rest, endianness, &h.filename, &h.datalength, &h.autostartline, &h.programlength); err != nil {
+			return nil, err
+		}
+		filename, err := filenameToString(h.filename)
+		if err != nil {
+			return nil, err
+		}
+		return BASICHeader{
+			Filename:      filename,
+			DataLength:    h.datalength,
+			AutostartLine: h.autostartline,
+			ProgramLength: h.programlength,
+		}, nil
+
+	case tapNumArrayHeaderType:
+		var h Block_NumArrayHeader
+		if err := readHeaderFields(rest, endianness, &h.filename, &h.datalength, &h.unused, &h.variablename, &h.unused2); err != nil {
+			return nil, err
+		}
+		filename, err := filenameToString(h.filename)
+		if err != nil {
+			return nil, err
+		}
+		return NumArrayHeader{
+			Filename:     filename,
+			DataLength:   h.datalength,
+			VariableName: 'A' + (h.variablename - 128) - 1,
+		}, nil
+
+	case tapStringArrayHeaderType:
+		var h Block_StringArrayHeader
+		if err := readHeaderFields(rest, endianness, &h.filename, &h.datalength, &h.unused, &h.variablename, &h.unused2); err != nil {
+			return nil, err
+		}
+		filename, err := filenameToString(h.filename)
+		if err != nil {
+			return nil, err
+		}
+		return StringArrayHeader{
+			Filename:     filename,
+			DataLength:   h.datalength,
+			VariableName: 'A' + (h.variablename - 192) - 1,
+		}, nil
+
+	default:
+		return nil, fmt.Errorf("tapfile: unknown header data type %#02x", datatype)
+	}
+}
+
+// readHeaderFields reads a sequence of fixed-size fields from a header block in order
+func readHeaderFields(r io.Reader, endianness binary.ByteOrder, fields ...interface{}) error {
+
+	for _, f := range fields {
+		if err := binary.Read(r, endianness, f); err != nil {
+			return fmt.Errorf("tapfile: reading header field: %s", err)
+		}
+	}
+
+	return nil
+}
+
+// filenameToString decodes and trims the trailing space padding off a raw 10-byte TAP filename
+// field. Each byte is decoded through the ZX Spectrum character set (which a plain 7-bit ASCII name
+// is already a subset of), so filenames written with either ASCIIEncoder or ZXSpectrumEncoder read
+// back correctly; a byte with no assigned meaning in that character set is rejected as a sign of
+// malformed input.
+func filenameToString(f [10]byte) (string, error) {
+
+	var name strings.Builder
+
+	for _, c := range f {
+		s, err := decodeZXByte(c)
+		if err != nil {
+			return "", fmt.Errorf("tapfile: %s in filename field", err)
+		}
+		name.WriteString(s)
+	}
+
+	return strings.TrimRight(name.String(), " "), nil
+}
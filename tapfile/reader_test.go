@@ -0,0 +1,82 @@
+// Copyright © 2019 Marton Magyar
+
+// SPDX-License-Identifier: MIT
+// see https://spdx.org/licenses/
+
+package tapfile
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// FuzzTAPReader feeds arbitrary bytes into NewReader and checks that the parser never panics and
+// always terminates, following the pattern of the archive/tar and archive/zip fuzz tests.
+func FuzzTAPReader(f *testing.F) {
+
+	seeds, err := filepath.Glob("testdata/*.tap")
+	if err != nil {
+		f.Fatal(err)
+	}
+
+	for _, seed := range seeds {
+		data, err := os.ReadFile(seed)
+		if err != nil {
+			f.Fatal(err)
+		}
+		f.Add(data)
+	}
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+
+		r := NewReader(bytes.NewReader(data))
+
+		for {
+			hdr, err := r.Next()
+			if err != nil {
+				// io.EOF is the normal end of stream; any other error is a rejection of
+				// malformed input, which is equally fine as long as it doesn't panic
+				return
+			}
+			if hdr == nil {
+				t.Fatal("Next returned a nil Header with a nil error")
+			}
+			if _, err := ioutil.ReadAll(r); err != nil {
+				return
+			}
+		}
+	})
+}
+
+// TestReader_ZXSpectrumFilenameRoundTrip checks that a filename encoded with ZXSpectrumEncoder,
+// including block-graphic and tokenised-keyword bytes, reads back as the same string.
+func TestReader_ZXSpectrumFilenameRoundTrip(t *testing.T) {
+
+	const name = "£OADER©█RND"
+
+	bin, err := NewBINdata(name, bytes.NewReader([]byte{1, 2, 3}), 32768, WithFilenameEncoder(ZXSpectrumEncoder{}))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var buf bytes.Buffer
+	if err := bin.Write(NewTAPfileBlockWriter(&buf)); err != nil {
+		t.Fatal(err)
+	}
+
+	hdr, err := NewReader(&buf).Next()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	bh, ok := hdr.(BytesHeader)
+	if !ok {
+		t.Fatalf("expected a BytesHeader, got %T", hdr)
+	}
+	if bh.Filename != name {
+		t.Fatalf("Filename = %q, want %q", bh.Filename, name)
+	}
+}
@@ -0,0 +1,74 @@
+// Copyright © 2019 Marton Magyar
+
+// SPDX-License-Identifier: MIT
+// see https://spdx.org/licenses/
+
+package tapfile
+
+import (
+	"io"
+)
+
+/*
+TAP file archive functions.
+
+This file provides TAPArchive, a sequential writer for building a single TAP file out of an
+arbitrary number of BASIC, bytes and array entries, mirroring the sequential-writer pattern of
+archive/tar.Writer and archive/zip.Writer.
+*/
+
+// TAPEntry is implemented by every TAP file entry that can be appended to a TAPArchive: BINdata,
+// BASICdata, NumArrayData and StringArrayData.
+type TAPEntry interface {
+	Write(w BlockWriter) error
+}
+
+// countingWriter tracks the total number of bytes written to an underlying io.Writer
+type countingWriter struct {
+	w io.Writer
+	n int
+}
+
+func (c *countingWriter) Write(p []byte) (int, error) {
+
+	n, err := c.w.Write(p)
+	c.n += n
+
+	return n, err
+}
+
+// TAPArchive sequentially writes TAP file entries into a single tape, one after another
+type TAPArchive struct {
+	cw *countingWriter
+	bw *TAPfileBlockWriter
+}
+
+// NewTAPArchive initializes and returns a TAPArchive writing to w
+func NewTAPArchive(w io.Writer) *TAPArchive {
+
+	a := new(TAPArchive)
+
+	a.cw = &countingWriter{w: w}
+	a.bw = NewTAPfileBlockWriter(a.cw)
+
+	return a
+}
+
+// Append writes entry's header and data blocks to the archive
+func (a *TAPArchive) Append(entry TAPEntry) error {
+
+	return entry.Write(a.bw)
+}
+
+// Len returns the total number of bytes written to the archive so far
+func (a *TAPArchive) Len() int {
+
+	return a.cw.n
+}
+
+// Close finalizes the archive. The TAP format has no trailer, so this currently only exists for
+// symmetry with archive/tar.Writer and other sequential writers.
+func (a *TAPArchive) Close() error {
+
+	return nil
+}
@@ -0,0 +1,82 @@
+// Copyright © 2019 Marton Magyar
+
+// SPDX-License-Identifier: MIT
+// see https://spdx.org/licenses/
+
+package tapfile
+
+import (
+	"bytes"
+	"io"
+	"io/ioutil"
+	"testing"
+)
+
+// TestTAPArchive_AppendMultipleEntries checks that a TAPArchive holding a BASIC loader followed by a
+// bytes entry reads back, through Reader.Next, as the same two header/data block pairs in order.
+func TestTAPArchive_AppendMultipleEntries(t *testing.T) {
+
+	basic, err := NewBASICdata("LOADER", bytes.NewReader([]byte("10 RUN\n")), 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	code, err := NewBINdata("CODE", bytes.NewReader([]byte{0xF3, 0xAF, 0xC9}), 32768)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var buf bytes.Buffer
+	archive := NewTAPArchive(&buf)
+
+	if err := archive.Append(basic); err != nil {
+		t.Fatal(err)
+	}
+	if err := archive.Append(code); err != nil {
+		t.Fatal(err)
+	}
+	if err := archive.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if archive.Len() != buf.Len() {
+		t.Errorf("Len() = %d, want %d", archive.Len(), buf.Len())
+	}
+
+	r := NewReader(&buf)
+
+	hdr, err := r.Next()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := hdr.(BASICHeader); !ok {
+		t.Fatalf("first entry: expected a BASICHeader, got %T", hdr)
+	}
+	if _, err := r.Next(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ioutil.ReadAll(r); err != nil {
+		t.Fatal(err)
+	}
+
+	hdr, err = r.Next()
+	if err != nil {
+		t.Fatal(err)
+	}
+	bh, ok := hdr.(BytesHeader)
+	if !ok {
+		t.Fatalf("second entry: expected a BytesHeader, got %T", hdr)
+	}
+	if bh.Filename != "CODE" {
+		t.Errorf("second entry: Filename = %q, want %q", bh.Filename, "CODE")
+	}
+	if _, err := r.Next(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ioutil.ReadAll(r); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := r.Next(); err != io.EOF {
+		t.Fatalf("Next() after the last entry = %v, want io.EOF", err)
+	}
+}
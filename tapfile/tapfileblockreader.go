@@ -0,0 +1,78 @@
+// Copyright © 2019 Marton Magyar
+
+// SPDX-License-Identifier: MIT
+// see https://spdx.org/licenses/
+
+package tapfile
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+)
+
+/*
+TAP file block reader functions.
+
+Reader functions for unwrapping blocks from the TAP file format, where a block is preceded by a length
+block and followed by a checksum.
+*/
+
+// ErrChecksum is returned by TAPfileBlockReader.ReadBlock when a block's trailing checksum byte does
+// not match the XOR of the block's contents.
+type ErrChecksum struct {
+	Expected uint8
+	Actual   uint8
+}
+
+func (e *ErrChecksum) Error() string {
+	return fmt.Sprintf("tapfile: checksum mismatch, expected %#02x, got %#02x", e.Expected, e.Actual)
+}
+
+// A TAPfileBlockReader implements a TAP file block unwrapper
+type TAPfileBlockReader struct {
+	rdr io.Reader
+}
+
+// NewTAPfileBlockReader initializes and returns a TAPfileBlockReader structure
+func NewTAPfileBlockReader(r io.Reader) *TAPfileBlockReader {
+
+	b := new(TAPfileBlockReader)
+
+	b.rdr = r
+
+	return b
+}
+
+// ReadBlock reads and unwraps the next TAP file block, returning its contents (the flag byte followed
+// by the block's data, with the length prefix and trailing checksum byte stripped off). It returns
+// io.EOF once no more blocks are available, and *ErrChecksum if a block's checksum does not match.
+func (b *TAPfileBlockReader) ReadBlock() ([]byte, error) {
+
+	var length uint16
+	if err := binary.Read(b.rdr, binary.LittleEndian, &length); err != nil {
+		if err == io.EOF {
+			return nil, io.EOF
+		}
+		return nil, fmt.Errorf("tapfile: reading block length: %s", err)
+	}
+
+	if length < 2 {
+		return nil, fmt.Errorf("tapfile: invalid block length %d, too short to hold a flag and checksum byte", length)
+	}
+
+	block := make([]byte, length)
+	if _, err := io.ReadFull(b.rdr, block); err != nil {
+		if err == io.EOF || err == io.ErrUnexpectedEOF {
+			return nil, fmt.Errorf("tapfile: truncated block, expected %d bytes: %s", length, io.ErrUnexpectedEOF)
+		}
+		return nil, err
+	}
+
+	data, checksum := block[:len(block)-1], block[len(block)-1]
+	if want := xorChecksum(data); want != checksum {
+		return nil, &ErrChecksum{Expected: want, Actual: checksum}
+	}
+
+	return data, nil
+}
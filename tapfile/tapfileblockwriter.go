@@ -19,6 +19,15 @@ Writer functions for wrapping blocks into the TAP file format, where a block is
 and followed by a checksum.
 */
 
+// BlockWriter is the shared block-emission interface TAPEntry writes through: buffer the block's
+// contents via Write, then wrap and emit them via CompleteBlock. TAPfileBlockWriter implements it for
+// plain TAP output; other packages (e.g. tzxfile) can implement it to feed the same entries into a
+// different container format.
+type BlockWriter interface {
+	io.Writer
+	CompleteBlock() error
+}
+
 // A TAPfileBlockWriter implements a TAP file block wrapper
 type TAPfileBlockWriter struct {
 	buf bytes.Buffer
@@ -0,0 +1,155 @@
+// Copyright © 2019 Marton Magyar
+
+// SPDX-License-Identifier: MIT
+// see https://spdx.org/licenses/
+
+package tzxfile
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+)
+
+/*
+TZX block writer functions.
+
+Block writers implement tapfile.BlockWriter, so any tapfile.TAPEntry can be written straight into a
+TZX file: entry.Write buffers the block's raw TAP-style flag+data bytes through Write, then
+CompleteBlock wraps them with the framing of the requested TZX block type.
+*/
+
+// standardSpeedBlockWriter wraps a block's contents as a TZX Standard Speed Data block (ID 0x10)
+type standardSpeedBlockWriter struct {
+	buf          bytes.Buffer
+	wtr          io.Writer
+	pauseAfterMs uint16
+}
+
+func newStandardSpeedBlockWriter(w io.Writer, pauseAfterMs uint16) *standardSpeedBlockWriter {
+
+	b := new(standardSpeedBlockWriter)
+
+	b.wtr = w
+	b.pauseAfterMs = pauseAfterMs
+
+	return b
+}
+
+func (b *standardSpeedBlockWriter) Write(p []byte) (int, error) {
+
+	if (len(p) + b.buf.Len()) > int(standardSpeedBlockMaxLength) {
+		return 0, fmt.Errorf("Write error, TZX standard speed data block is going to become longer than max length of %d", standardSpeedBlockMaxLength)
+	}
+
+	return b.buf.Write(p)
+}
+
+func (b *standardSpeedBlockWriter) CompleteBlock() error {
+
+	defer b.buf.Reset()
+
+	endianness := binary.LittleEndian
+
+	if err := binary.Write(b.wtr, endianness, blockStandardSpeedData); err != nil {
+		return err
+	}
+	if err := binary.Write(b.wtr, endianness, b.pauseAfterMs); err != nil {
+		return err
+	}
+	if err := binary.Write(b.wtr, endianness, uint16(b.buf.Len()+1)); err != nil {
+		return err
+	}
+	if err := binary.Write(b.wtr, endianness, b.buf.Bytes()); err != nil {
+		return err
+	}
+
+	return binary.Write(b.wtr, endianness, xorChecksum(b.buf.Bytes()))
+}
+
+// TurboTiming carries the pilot/sync/zero/one pulse lengths (in T-states) and pause used to wrap a
+// block as a TZX Turbo Speed Data block, for custom fast-loaders that standard speed cannot represent
+type TurboTiming struct {
+	PilotPulseLength      uint16
+	SyncFirstPulseLength  uint16
+	SyncSecondPulseLength uint16
+	ZeroBitPulseLength    uint16
+	OneBitPulseLength     uint16
+	PilotToneLength       uint16 // number of pulses in the pilot tone
+	UsedBitsInLastByte    uint8
+	PauseAfterMs          uint16
+}
+
+// turboSpeedBlockWriter wraps a block's contents as a TZX Turbo Speed Data block (ID 0x11)
+type turboSpeedBlockWriter struct {
+	buf    bytes.Buffer
+	wtr    io.Writer
+	timing TurboTiming
+}
+
+func newTurboSpeedBlockWriter(w io.Writer, timing TurboTiming) *turboSpeedBlockWriter {
+
+	b := new(turboSpeedBlockWriter)
+
+	b.wtr = w
+	b.timing = timing
+
+	return b
+}
+
+func (b *turboSpeedBlockWriter) Write(p []byte) (int, error) {
+
+	if (len(p) + b.buf.Len()) > int(turboSpeedBlockMaxLength) {
+		return 0, fmt.Errorf("Write error, TZX turbo speed data block is going to become longer than max length of %d", turboSpeedBlockMaxLength)
+	}
+
+	return b.buf.Write(p)
+}
+
+func (b *turboSpeedBlockWriter) CompleteBlock() error {
+
+	defer b.buf.Reset()
+
+	endianness := binary.LittleEndian
+
+	if err := binary.Write(b.wtr, endianness, blockTurboSpeedData); err != nil {
+		return err
+	}
+	if err := binary.Write(b.wtr, endianness, b.timing.PilotPulseLength); err != nil {
+		return err
+	}
+	if err := binary.Write(b.wtr, endianness, b.timing.SyncFirstPulseLength); err != nil {
+		return err
+	}
+	if err := binary.Write(b.wtr, endianness, b.timing.SyncSecondPulseLength); err != nil {
+		return err
+	}
+	if err := binary.Write(b.wtr, endianness, b.timing.ZeroBitPulseLength); err != nil {
+		return err
+	}
+	if err := binary.Write(b.wtr, endianness, b.timing.OneBitPulseLength); err != nil {
+		return err
+	}
+	if err := binary.Write(b.wtr, endianness, b.timing.PilotToneLength); err != nil {
+		return err
+	}
+	if err := binary.Write(b.wtr, endianness, b.timing.UsedBitsInLastByte); err != nil {
+		return err
+	}
+	if err := binary.Write(b.wtr, endianness, b.timing.PauseAfterMs); err != nil {
+		return err
+	}
+
+	length := make([]byte, 3)
+	putUint24(length, uint32(b.buf.Len()+1))
+	if _, err := b.wtr.Write(length); err != nil {
+		return err
+	}
+
+	if err := binary.Write(b.wtr, endianness, b.buf.Bytes()); err != nil {
+		return err
+	}
+
+	return binary.Write(b.wtr, endianness, xorChecksum(b.buf.Bytes()))
+}
@@ -0,0 +1,58 @@
+// Copyright © 2019 Marton Magyar
+
+// SPDX-License-Identifier: MIT
+// see https://spdx.org/licenses/
+
+package tzxfile
+
+import "math"
+
+/*
+TZX file format definitions.
+See: https://worldofspectrum.net/TZXformat.html
+
+TZX is a superset of the TAP format used by modern emulators and real-hardware loaders. A TZX file
+starts with a fixed signature and version, followed by an arbitrary sequence of blocks, each introduced
+by a one-byte block ID.
+*/
+
+const tzxSignature = "ZXTape!"
+const tzxEOFMarker uint8 = 0x1A
+
+const tzxMajorVersion uint8 = 1
+const tzxMinorVersion uint8 = 20
+
+const blockStandardSpeedData uint8 = 0x10
+const blockTurboSpeedData uint8 = 0x11
+const blockTextDescription uint8 = 0x30
+const blockArchiveInfo uint8 = 0x32
+
+// archive info text IDs, see the TZX spec for the full list
+const archiveInfoAuthor uint8 = 0x02
+const archiveInfoYear uint8 = 0x03
+
+// standardSpeedBlockMaxLength bounds the buffered flag+data length a standard speed data block can
+// hold, leaving room for the trailing +1 that goes into its 16-bit data length field, mirroring
+// tapfile.tapBlockMaxLength.
+const standardSpeedBlockMaxLength uint16 = math.MaxUint16 - 2
+
+// turboSpeedBlockMaxLength is the same bound for a turbo speed data block's 24-bit data length field
+const turboSpeedBlockMaxLength uint32 = 1<<24 - 1 - 2
+
+func xorChecksum(data []byte) uint8 {
+
+	var cs byte = 0
+	for _, b := range data {
+		cs = cs ^ b
+	}
+	return cs
+}
+
+// putUint24 writes v (which must fit in 24 bits) to b in little-endian order, as used by the TZX
+// turbo speed data block's data length field
+func putUint24(b []byte, v uint32) {
+
+	b[0] = byte(v)
+	b[1] = byte(v >> 8)
+	b[2] = byte(v >> 16)
+}
@@ -0,0 +1,146 @@
+// Copyright © 2019 Marton Magyar
+
+// SPDX-License-Identifier: MIT
+// see https://spdx.org/licenses/
+
+package tzxfile
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+
+	"github.com/retro-speccy/bin2tap/tapfile"
+)
+
+// defaultPauseAfterMs is the pause (in milliseconds) emitted after a standard speed data block unless
+// overridden with SetPauseAfter
+const defaultPauseAfterMs uint16 = 1000
+
+// TZXWriter sequentially writes TAP entries into a single TZX file, the same way TAPArchive does for
+// plain TAP files, but carries the extra turbo/pilot timing and descriptive metadata blocks TAP cannot.
+type TZXWriter struct {
+	w            io.Writer
+	pauseAfterMs uint16
+}
+
+// NewTZXWriter initializes a TZXWriter, immediately writing the TZX signature and version to w
+func NewTZXWriter(w io.Writer) (*TZXWriter, error) {
+
+	t := new(TZXWriter)
+
+	t.w = w
+	t.pauseAfterMs = defaultPauseAfterMs
+
+	if err := t.writeSignature(); err != nil {
+		return nil, err
+	}
+
+	return t, nil
+}
+
+func (t *TZXWriter) writeSignature() error {
+
+	if _, err := io.WriteString(t.w, tzxSignature); err != nil {
+		return err
+	}
+	if err := binary.Write(t.w, binary.LittleEndian, tzxEOFMarker); err != nil {
+		return err
+	}
+	if err := binary.Write(t.w, binary.LittleEndian, tzxMajorVersion); err != nil {
+		return err
+	}
+
+	return binary.Write(t.w, binary.LittleEndian, tzxMinorVersion)
+}
+
+// SetPauseAfter sets the pause, in milliseconds, emitted after each subsequently appended standard
+// speed block
+func (t *TZXWriter) SetPauseAfter(ms uint16) {
+
+	t.pauseAfterMs = ms
+}
+
+// Append writes entry's header and data blocks as TZX Standard Speed Data blocks (ID 0x10)
+func (t *TZXWriter) Append(entry tapfile.TAPEntry) error {
+
+	return entry.Write(newStandardSpeedBlockWriter(t.w, t.pauseAfterMs))
+}
+
+// AppendTurbo writes entry's header and data blocks as TZX Turbo Speed Data blocks (ID 0x11), using
+// the given pilot/sync/zero/one pulse timing instead of standard ROM loading speed
+func (t *TZXWriter) AppendTurbo(entry tapfile.TAPEntry, timing TurboTiming) error {
+
+	return entry.Write(newTurboSpeedBlockWriter(t.w, timing))
+}
+
+// AppendDescription writes a TZX Text Description block (ID 0x30), e.g. to hold the tape's title
+func (t *TZXWriter) AppendDescription(text string) error {
+
+	if len(text) > 255 {
+		return fmt.Errorf("tzxfile: description too long, %d bytes, max 255", len(text))
+	}
+
+	if err := binary.Write(t.w, binary.LittleEndian, blockTextDescription); err != nil {
+		return err
+	}
+	if err := binary.Write(t.w, binary.LittleEndian, uint8(len(text))); err != nil {
+		return err
+	}
+
+	_, err := io.WriteString(t.w, text)
+	return err
+}
+
+// AppendArchiveInfo writes a TZX Archive Info block (ID 0x32) carrying the author and year of
+// publication of the tape. Either field may be left empty to omit it.
+func (t *TZXWriter) AppendArchiveInfo(author, year string) error {
+
+	var body bytes.Buffer
+
+	texts := []struct {
+		id   uint8
+		text string
+	}{
+		{archiveInfoAuthor, author},
+		{archiveInfoYear, year},
+	}
+
+	var count uint8
+	for _, e := range texts {
+		if e.text != "" {
+			count++
+		}
+	}
+	body.WriteByte(count)
+
+	for _, e := range texts {
+		if e.text == "" {
+			continue
+		}
+		if len(e.text) > 255 {
+			return fmt.Errorf("tzxfile: archive info text too long, %d bytes, max 255", len(e.text))
+		}
+		body.WriteByte(e.id)
+		body.WriteByte(uint8(len(e.text)))
+		body.WriteString(e.text)
+	}
+
+	if err := binary.Write(t.w, binary.LittleEndian, blockArchiveInfo); err != nil {
+		return err
+	}
+	if err := binary.Write(t.w, binary.LittleEndian, uint16(body.Len())); err != nil {
+		return err
+	}
+
+	_, err := t.w.Write(body.Bytes())
+	return err
+}
+
+// Close finalizes the TZX file. TZX has no trailer, so this currently only exists for symmetry with
+// tapfile.TAPArchive and other sequential writers.
+func (t *TZXWriter) Close() error {
+
+	return nil
+}
@@ -0,0 +1,223 @@
+// Copyright © 2019 Marton Magyar
+
+// SPDX-License-Identifier: MIT
+// see https://spdx.org/licenses/
+
+package tzxfile
+
+import (
+	"bytes"
+	"encoding/binary"
+	"testing"
+
+	"github.com/retro-speccy/bin2tap/tapfile"
+)
+
+// readSignature checks and strips the TZX signature and version bytes off the front of data
+func readSignature(t *testing.T, data []byte) []byte {
+	t.Helper()
+
+	want := append([]byte(tzxSignature), tzxEOFMarker, tzxMajorVersion, tzxMinorVersion)
+	if !bytes.Equal(data[:len(want)], want) {
+		t.Fatalf("signature = % X, want % X", data[:len(want)], want)
+	}
+
+	return data[len(want):]
+}
+
+// readStandardSpeedBlock checks the framing of a TZX Standard Speed Data block (ID 0x10) at the
+// front of data, verifies its checksum, and returns its flag+data payload and the remaining bytes
+func readStandardSpeedBlock(t *testing.T, data []byte, wantPauseMs uint16) (payload []byte, rest []byte) {
+	t.Helper()
+
+	if data[0] != blockStandardSpeedData {
+		t.Fatalf("block ID = %#02x, want %#02x", data[0], blockStandardSpeedData)
+	}
+	if pause := binary.LittleEndian.Uint16(data[1:3]); pause != wantPauseMs {
+		t.Fatalf("pause after = %d ms, want %d ms", pause, wantPauseMs)
+	}
+
+	length := binary.LittleEndian.Uint16(data[3:5])
+	body := data[5 : 5+int(length)]
+	payload, checksum := body[:len(body)-1], body[len(body)-1]
+	if got := xorChecksum(payload); got != checksum {
+		t.Fatalf("checksum = %#02x, want %#02x", checksum, got)
+	}
+
+	return payload, data[5+int(length):]
+}
+
+// TestTZXWriter_Append checks that Append wraps a TAP entry's header and data blocks as two TZX
+// Standard Speed Data blocks, with the writer's configured pause and a correct length/checksum.
+func TestTZXWriter_Append(t *testing.T) {
+
+	bin, err := tapfile.NewBINdata("CODE", bytes.NewReader([]byte{0xF3, 0xAF, 0xC9}), 32768)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var buf bytes.Buffer
+	tzx, err := NewTZXWriter(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tzx.SetPauseAfter(500)
+
+	if err := tzx.Append(bin); err != nil {
+		t.Fatal(err)
+	}
+	if err := tzx.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	rest := readSignature(t, buf.Bytes())
+
+	headerPayload, rest := readStandardSpeedBlock(t, rest, 500)
+	if headerPayload[0] != 0x00 {
+		t.Errorf("header flag = %#02x, want 0x00", headerPayload[0])
+	}
+
+	dataPayload, rest := readStandardSpeedBlock(t, rest, 500)
+	if dataPayload[0] != 0xFF {
+		t.Errorf("data flag = %#02x, want 0xFF", dataPayload[0])
+	}
+	if !bytes.Equal(dataPayload[1:], []byte{0xF3, 0xAF, 0xC9}) {
+		t.Errorf("data = % X, want % X", dataPayload[1:], []byte{0xF3, 0xAF, 0xC9})
+	}
+
+	if len(rest) != 0 {
+		t.Errorf("%d trailing bytes after the expected two blocks", len(rest))
+	}
+}
+
+// TestTZXWriter_AppendTurbo checks that AppendTurbo wraps a TAP entry as a TZX Turbo Speed Data
+// block (ID 0x11) carrying the requested pilot/sync/zero/one pulse timing.
+func TestTZXWriter_AppendTurbo(t *testing.T) {
+
+	bin, err := tapfile.NewBINdata("CODE", bytes.NewReader([]byte{1, 2, 3}), 32768)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	timing := TurboTiming{
+		PilotPulseLength: 2168, SyncFirstPulseLength: 667, SyncSecondPulseLength: 735,
+		ZeroBitPulseLength: 855, OneBitPulseLength: 1710, PilotToneLength: 8063,
+		UsedBitsInLastByte: 8, PauseAfterMs: 1000,
+	}
+
+	var buf bytes.Buffer
+	tzx, err := NewTZXWriter(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := tzx.AppendTurbo(bin, timing); err != nil {
+		t.Fatal(err)
+	}
+
+	data := readSignature(t, buf.Bytes())
+
+	if data[0] != blockTurboSpeedData {
+		t.Fatalf("block ID = %#02x, want %#02x", data[0], blockTurboSpeedData)
+	}
+	fields := []struct {
+		name string
+		want uint16
+		got  uint16
+	}{
+		{"PilotPulseLength", timing.PilotPulseLength, binary.LittleEndian.Uint16(data[1:3])},
+		{"SyncFirstPulseLength", timing.SyncFirstPulseLength, binary.LittleEndian.Uint16(data[3:5])},
+		{"SyncSecondPulseLength", timing.SyncSecondPulseLength, binary.LittleEndian.Uint16(data[5:7])},
+		{"ZeroBitPulseLength", timing.ZeroBitPulseLength, binary.LittleEndian.Uint16(data[7:9])},
+		{"OneBitPulseLength", timing.OneBitPulseLength, binary.LittleEndian.Uint16(data[9:11])},
+		{"PilotToneLength", timing.PilotToneLength, binary.LittleEndian.Uint16(data[11:13])},
+	}
+	for _, f := range fields {
+		if f.got != f.want {
+			t.Errorf("%s = %d, want %d", f.name, f.got, f.want)
+		}
+	}
+	if data[13] != timing.UsedBitsInLastByte {
+		t.Errorf("UsedBitsInLastByte = %d, want %d", data[13], timing.UsedBitsInLastByte)
+	}
+	if pause := binary.LittleEndian.Uint16(data[14:16]); pause != timing.PauseAfterMs {
+		t.Errorf("PauseAfterMs = %d, want %d", pause, timing.PauseAfterMs)
+	}
+
+	length := uint32(data[16]) | uint32(data[17])<<8 | uint32(data[18])<<16
+	body := data[19 : 19+int(length)]
+	payload, checksum := body[:len(body)-1], body[len(body)-1]
+	if got := xorChecksum(payload); got != checksum {
+		t.Fatalf("checksum = %#02x, want %#02x", checksum, got)
+	}
+}
+
+// TestTZXWriter_AppendDescription checks the framing of a TZX Text Description block (ID 0x30).
+func TestTZXWriter_AppendDescription(t *testing.T) {
+
+	var buf bytes.Buffer
+	tzx, err := NewTZXWriter(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := tzx.AppendDescription("DEMO TAPE"); err != nil {
+		t.Fatal(err)
+	}
+
+	data := readSignature(t, buf.Bytes())
+
+	if data[0] != blockTextDescription {
+		t.Fatalf("block ID = %#02x, want %#02x", data[0], blockTextDescription)
+	}
+	if data[1] != byte(len("DEMO TAPE")) {
+		t.Fatalf("text length = %d, want %d", data[1], len("DEMO TAPE"))
+	}
+	if got := string(data[2 : 2+data[1]]); got != "DEMO TAPE" {
+		t.Errorf("text = %q, want %q", got, "DEMO TAPE")
+	}
+}
+
+// TestTZXWriter_AppendArchiveInfo checks the framing of a TZX Archive Info block (ID 0x32) carrying
+// both the author and year text entries.
+func TestTZXWriter_AppendArchiveInfo(t *testing.T) {
+
+	var buf bytes.Buffer
+	tzx, err := NewTZXWriter(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := tzx.AppendArchiveInfo("A. Author", "2026"); err != nil {
+		t.Fatal(err)
+	}
+
+	data := readSignature(t, buf.Bytes())
+
+	if data[0] != blockArchiveInfo {
+		t.Fatalf("block ID = %#02x, want %#02x", data[0], blockArchiveInfo)
+	}
+	length := binary.LittleEndian.Uint16(data[1:3])
+	body := data[3 : 3+int(length)]
+
+	if body[0] != 2 {
+		t.Fatalf("entry count = %d, want 2", body[0])
+	}
+
+	pos := 1
+	if body[pos] != archiveInfoAuthor {
+		t.Fatalf("first entry id = %#02x, want %#02x", body[pos], archiveInfoAuthor)
+	}
+	authorLen := int(body[pos+1])
+	author := string(body[pos+2 : pos+2+authorLen])
+	if author != "A. Author" {
+		t.Errorf("author = %q, want %q", author, "A. Author")
+	}
+	pos += 2 + authorLen
+
+	if body[pos] != archiveInfoYear {
+		t.Fatalf("second entry id = %#02x, want %#02x", body[pos], archiveInfoYear)
+	}
+	yearLen := int(body[pos+1])
+	year := string(body[pos+2 : pos+2+yearLen])
+	if year != "2026" {
+		t.Errorf("year = %q, want %q", year, "2026")
+	}
+}